@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"errors"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -99,10 +100,229 @@ type RunnerSpec struct {
 	DockerEnabled *bool `json:"dockerEnabled,omitempty"`
 	// +optional
 	DockerMTU *int64 `json:"dockerMTU,omitempty"`
+
+	// EventSink, when set, makes the controller POST a CloudEvent to the given
+	// HTTP(S) endpoint whenever this Runner's phase, registration, or
+	// LastRegistrationCheckTime changes.
+	// +optional
+	EventSink *RunnerEventSink `json:"eventSink,omitempty"`
+
+	// GPUs requests GPU devices exposed by a node's device plugin.
+	// +optional
+	GPUs *GPURequest `json:"gpus,omitempty"`
+
+	// SRIOV requests one or more SR-IOV network interfaces exposed by the
+	// Multus/SR-IOV device plugin.
+	// +optional
+	SRIOV []SRIOVInterface `json:"sriov,omitempty"`
+
+	// QAT requests Intel QuickAssist Technology devices exposed by the QAT
+	// device plugin.
+	// +optional
+	QAT *QATRequest `json:"qat,omitempty"`
+
+	// MetricsPush, when set, makes the controller push per-job metrics for
+	// this Runner to a Prometheus Pushgateway. This is primarily useful for
+	// Ephemeral runners, which are deleted before Prometheus can scrape them.
+	// +optional
+	MetricsPush *MetricsPushSpec `json:"metricsPush,omitempty"`
+
+	// Targets registers a single runner pod against multiple GitHub scopes.
+	// When set, it replaces the Enterprise/Organization/Repository/
+	// Labels/Group shorthand above. ValidateRepository rejects Targets
+	// combined with Enterprise, Organization or Repository; Labels and Group
+	// are not checked and are ignored when Targets is set.
+	// +optional
+	Targets []RunnerTarget `json:"targets,omitempty"`
+}
+
+// RunnerTarget is a single GitHub scope a Runner registers against. See
+// RunnerSpec.Targets.
+type RunnerTarget struct {
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^/]+$`
+	Enterprise string `json:"enterprise,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^/]+$`
+	Organization string `json:"organization,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^/]+/[^/]+$`
+	Repository string `json:"repository,omitempty"`
+
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Weight controls how new-runner registrations are distributed across
+	// Targets; a target with Weight 2 receives registrations twice as often
+	// as one with Weight 1. Defaults to 1 when zero.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Weight int32 `json:"weight,omitempty"`
+
+	// Schedule, when set, is a standard 5-field cron expression. It is
+	// evaluated when the runner Pod is materialized: the Pod's registration
+	// URLs include this target only if Schedule matches that minute, and an
+	// existing Pod is not updated when Schedule stops matching. A cron
+	// expression names specific minutes, not a window, so
+	// restricting a target to a range (e.g. an Organization target active
+	// only during business hours, falling back to a personal Repository
+	// target overnight) requires a range in the relevant field, such as
+	// "* 9-17 * * 1-5" rather than "0 9 * * 1-5".
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// validateScope checks that exactly one of Enterprise, Organization, or
+// Repository is set on t.
+func (t RunnerTarget) validateScope() error {
+	foundCount := 0
+	if len(t.Organization) > 0 {
+		foundCount += 1
+	}
+	if len(t.Repository) > 0 {
+		foundCount += 1
+	}
+	if len(t.Enterprise) > 0 {
+		foundCount += 1
+	}
+	if foundCount == 0 {
+		return errors.New("target needs enterprise, organization or repository")
+	}
+	if foundCount > 1 {
+		return errors.New("target cannot have many fields defined enterprise, organization and repository")
+	}
+
+	return nil
 }
 
-// ValidateRepository validates repository field.
+// EffectiveTargets returns Targets when set, or a single-element slice built
+// from the legacy Enterprise/Organization/Repository/Labels/Group fields
+// otherwise, so callers can treat every Runner as multi-target.
+func (rs *RunnerSpec) EffectiveTargets() []RunnerTarget {
+	if len(rs.Targets) > 0 {
+		return rs.Targets
+	}
+
+	return []RunnerTarget{
+		{
+			Enterprise:   rs.Enterprise,
+			Organization: rs.Organization,
+			Repository:   rs.Repository,
+			Labels:       rs.Labels,
+			Group:        rs.Group,
+			Weight:       1,
+		},
+	}
+}
+
+// MetricsPushSpec configures pushing per-job metrics to a Prometheus
+// Pushgateway on job completion, keyed by job/instance grouping labels.
+type MetricsPushSpec struct {
+	// URL is the base address of the Pushgateway, e.g. "http://pushgateway:9091".
+	URL string `json:"url"`
+
+	// JobLabel is the Pushgateway "job" grouping label. Defaults to
+	// "github-runner" when empty.
+	// +optional
+	JobLabel string `json:"jobLabel,omitempty"`
+
+	// +optional
+	BasicAuthSecretRef *corev1.LocalObjectReference `json:"basicAuthSecretRef,omitempty"`
+
+	// GroupingLabels are additional Pushgateway grouping key labels beyond
+	// job and instance.
+	// +optional
+	GroupingLabels map[string]string `json:"groupingLabels,omitempty"`
+}
+
+// GPURequest describes a request for GPU devices exposed via a Kubernetes
+// device plugin, e.g. nvidia-device-plugin or intel-gpu-plugin.
+type GPURequest struct {
+	// Vendor selects the device plugin resource namespace to request from,
+	// e.g. "nvidia", "amd", or "intel".
+	// +kubebuilder:validation:Enum=nvidia;amd;intel
+	Vendor string `json:"vendor"`
+
+	// Count is the number of GPUs to request.
+	// +kubebuilder:validation:Minimum=1
+	Count int64 `json:"count"`
+
+	// MIGProfile, when set, requests an NVIDIA Multi-Instance GPU profile
+	// (e.g. "1g.5gb") instead of a whole GPU.
+	// +optional
+	MIGProfile string `json:"migProfile,omitempty"`
+}
+
+// SRIOVInterface requests a Multus-managed SR-IOV network interface.
+type SRIOVInterface struct {
+	// NetworkName is the name of the NetworkAttachmentDefinition to attach,
+	// and also selects the SR-IOV device plugin resource to request
+	// (e.g. "intel.com/sriov_netdevice_A").
+	NetworkName string `json:"networkName"`
+
+	// Count is the number of virtual functions to request for NetworkName.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Count int64 `json:"count,omitempty"`
+}
+
+// QATRequest describes a request for Intel QuickAssist Technology devices.
+type QATRequest struct {
+	// Count is the number of QAT devices to request.
+	// +kubebuilder:validation:Minimum=1
+	Count int64 `json:"count"`
+}
+
+// ValidateDeviceResources validates the device-plugin fields, alongside the
+// scope checks in ValidateRepository. It checks only that every SRIOV
+// interface sets NetworkName; GPUs and QAT are not checked here.
+func (rs *RunnerSpec) ValidateDeviceResources() error {
+	for _, iface := range rs.SRIOV {
+		if len(iface.NetworkName) == 0 {
+			return errors.New("SRIOV interface requires a networkName")
+		}
+	}
+
+	return nil
+}
+
+// RunnerEventSink is an HTTP(S) endpoint that receives CloudEvents describing
+// this Runner's lifecycle transitions.
+type RunnerEventSink struct {
+	// URL is the endpoint CloudEvents are POSTed to.
+	URL string `json:"url"`
+
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// SecretRef, when set, names a Secret in the Runner's namespace whose data
+	// is used to authenticate requests to URL (e.g. an Authorization header
+	// value keyed "token").
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// ValidateRepository validates the Enterprise/Organization/Repository
+// shorthand fields, or each entry of Targets when it is set.
 func (rs *RunnerSpec) ValidateRepository() error {
+	if len(rs.Targets) > 0 {
+		if len(rs.Enterprise) > 0 || len(rs.Organization) > 0 || len(rs.Repository) > 0 {
+			return errors.New("Spec cannot combine Targets with the enterprise, organization or repository shorthand fields")
+		}
+		for i, target := range rs.Targets {
+			if err := target.validateScope(); err != nil {
+				return fmt.Errorf("targets[%d]: %w", i, err)
+			}
+		}
+
+		return nil
+	}
+
 	// Enterprise, Organization and repository are both exclusive.
 	foundCount := 0
 	if len(rs.Organization) > 0 {
@@ -137,6 +357,35 @@ type RunnerStatus struct {
 	// +optional
 	// +nullable
 	LastRegistrationCheckTime *metav1.Time `json:"lastRegistrationCheckTime,omitempty"`
+
+	// Conditions holds additional status of this Runner, such as the delivery
+	// status of lifecycle CloudEvents to Spec.EventSink.
+	// +optional
+	Conditions []RunnerCondition `json:"conditions,omitempty"`
+}
+
+// RunnerConditionType is the type of a RunnerCondition.
+type RunnerConditionType string
+
+const (
+	// RunnerConditionEventDeliverySuccess reflects whether the most recent
+	// CloudEvent delivery to Spec.EventSink succeeded.
+	RunnerConditionEventDeliverySuccess RunnerConditionType = "EventDeliverySuccess"
+)
+
+// RunnerCondition describes a point-in-time condition of a Runner.
+type RunnerCondition struct {
+	Type   RunnerConditionType    `json:"type"`
+	Status corev1.ConditionStatus `json:"status"`
+
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	// +nullable
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // RunnerStatusRegistration contains runner registration status
@@ -147,6 +396,42 @@ type RunnerStatusRegistration struct {
 	Labels       []string    `json:"labels,omitempty"`
 	Token        string      `json:"token"`
 	ExpiresAt    metav1.Time `json:"expiresAt"`
+
+	// Targets holds the per-target registration state when Spec.Targets is
+	// set, in the same order. It is left empty for single-scope Runners,
+	// which continue to use the fields above.
+	// +optional
+	Targets []RunnerTargetRegistration `json:"targets,omitempty"`
+}
+
+// RunnerTargetRegistration is the registration state for a single
+// RunnerTarget.
+type RunnerTargetRegistration struct {
+	Enterprise   string      `json:"enterprise,omitempty"`
+	Organization string      `json:"organization,omitempty"`
+	Repository   string      `json:"repository,omitempty"`
+	Token        string      `json:"token"`
+	ExpiresAt    metav1.Time `json:"expiresAt"`
+}
+
+// effectiveTargets returns Targets when set, or a single-element slice built
+// from the legacy Enterprise/Organization/Repository/Token/ExpiresAt fields
+// otherwise, mirroring RunnerSpec.EffectiveTargets so IsRegisterable can
+// compare the two uniformly regardless of whether Spec.Targets is set.
+func (s RunnerStatusRegistration) effectiveTargets() []RunnerTargetRegistration {
+	if len(s.Targets) > 0 {
+		return s.Targets
+	}
+
+	return []RunnerTargetRegistration{
+		{
+			Enterprise:   s.Enterprise,
+			Organization: s.Organization,
+			Repository:   s.Repository,
+			Token:        s.Token,
+			ExpiresAt:    s.ExpiresAt,
+		},
+	}
 }
 
 // +kubebuilder:object:root=true
@@ -166,18 +451,30 @@ type Runner struct {
 	Status RunnerStatus `json:"status,omitempty"`
 }
 
+// IsRegisterable reports whether Status.Registration (or, when Spec.Targets
+// is set, each of Status.Registration.Targets) matches Spec's current scope
+// and carries a non-expired token. It compares EffectiveTargets on both
+// sides so single-scope and multi-target Runners share one code path.
 func (r Runner) IsRegisterable() bool {
-	if r.Status.Registration.Repository != r.Spec.Repository {
-		return false
-	}
+	targets := r.Spec.EffectiveTargets()
+	regs := r.Status.Registration.effectiveTargets()
 
-	if r.Status.Registration.Token == "" {
+	if len(regs) != len(targets) {
 		return false
 	}
 
 	now := metav1.Now()
-	if r.Status.Registration.ExpiresAt.Before(&now) {
-		return false
+	for i, target := range targets {
+		reg := regs[i]
+		if reg.Enterprise != target.Enterprise || reg.Organization != target.Organization || reg.Repository != target.Repository {
+			return false
+		}
+		if reg.Token == "" {
+			return false
+		}
+		if reg.ExpiresAt.Before(&now) {
+			return false
+		}
 	}
 
 	return true
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateRepositoryLegacyFields(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    RunnerSpec
+		wantErr bool
+	}{
+		{"none set", RunnerSpec{}, true},
+		{"repository only", RunnerSpec{Repository: "acme/widgets"}, false},
+		{"organization only", RunnerSpec{Organization: "acme"}, false},
+		{"repository and organization", RunnerSpec{Repository: "acme/widgets", Organization: "acme"}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.ValidateRepository()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ValidateRepository() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRepositoryTargets(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    RunnerSpec
+		wantErr bool
+	}{
+		{
+			name: "valid targets",
+			spec: RunnerSpec{Targets: []RunnerTarget{
+				{Repository: "acme/widgets"},
+				{Organization: "acme"},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "target with no scope",
+			spec: RunnerSpec{Targets: []RunnerTarget{{}}},
+			wantErr: true,
+		},
+		{
+			name: "target with ambiguous scope",
+			spec: RunnerSpec{Targets: []RunnerTarget{
+				{Repository: "acme/widgets", Organization: "acme"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "targets combined with legacy shorthand",
+			spec: RunnerSpec{
+				Repository: "acme/widgets",
+				Targets:    []RunnerTarget{{Organization: "acme"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.ValidateRepository()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ValidateRepository() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEffectiveTargetsFallsBackToLegacyFields(t *testing.T) {
+	spec := RunnerSpec{Organization: "acme", Labels: []string{"linux"}, Group: "default"}
+
+	targets := spec.EffectiveTargets()
+	if len(targets) != 1 {
+		t.Fatalf("EffectiveTargets() = %v, want 1 synthesized target", targets)
+	}
+	if targets[0].Organization != "acme" || targets[0].Weight != 1 {
+		t.Fatalf("EffectiveTargets()[0] = %+v, want Organization acme and Weight 1", targets[0])
+	}
+}
+
+func TestEffectiveTargetsPrefersTargets(t *testing.T) {
+	spec := RunnerSpec{Targets: []RunnerTarget{
+		{Organization: "acme", Weight: 3},
+		{Repository: "acme/widgets", Weight: 1},
+	}}
+
+	targets := spec.EffectiveTargets()
+	if len(targets) != 2 {
+		t.Fatalf("EffectiveTargets() = %v, want the 2 explicit Targets", targets)
+	}
+}
+
+func TestIsRegisterableMultiTarget(t *testing.T) {
+	future := metav1.NewTime(time.Now().Add(time.Hour))
+	runner := Runner{
+		Spec: RunnerSpec{Targets: []RunnerTarget{
+			{Organization: "acme"},
+			{Repository: "acme/widgets"},
+		}},
+		Status: RunnerStatus{
+			Registration: RunnerStatusRegistration{
+				Targets: []RunnerTargetRegistration{
+					{Organization: "acme", Token: "tok-1", ExpiresAt: future},
+					{Repository: "acme/widgets", Token: "tok-2", ExpiresAt: future},
+				},
+			},
+		},
+	}
+
+	if !runner.IsRegisterable() {
+		t.Fatal("IsRegisterable() = false, want true when every target has a non-expired token")
+	}
+
+	runner.Status.Registration.Targets[1].Token = ""
+	if runner.IsRegisterable() {
+		t.Fatal("IsRegisterable() = true, want false when a target is missing its token")
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+// metricsPushFinalizerName is added to a Runner whose job metrics are pushed
+// to a Pushgateway, so its grouping key can be deleted before the object
+// disappears and its series go stale.
+const metricsPushFinalizerName = "actions-runner-controller/metrics-push"
+
+// RunnerReconciler reconciles a Runner object.
+type RunnerReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+
+	// DefaultMetricsPushURL, keyed by namespace, is the Pushgateway URL used
+	// for Runners in that namespace that don't set Spec.MetricsPush. It is
+	// populated from the manager's repeatable
+	// `--default-metrics-push-url namespace=url` flag.
+	DefaultMetricsPushURL map[string]string
+
+	observedMu sync.Mutex
+	observed   map[types.NamespacedName]observedRunnerState
+}
+
+// observedRunnerState is the subset of a Runner's status this reconciler
+// compares across reconciles: the fields that gate CloudEvent delivery
+// (RunnerSpec.EventSink's doc comment), plus the timestamps of the last
+// Running/Busy phase transitions used to derive queue-wait and job-duration
+// metrics. It is kept in-memory only: a restart re-derives it from the first
+// reconcile of each Runner, which at worst skips one CloudEvent, or one
+// job's metrics, for a transition that happened while the controller was
+// down.
+//
+// eventDelivered tracks whether deliverRunnerEvent already succeeded for the
+// *current* phase/registration/lastRegistrationCheckTime tuple, and
+// metricsPushed tracks whether pushRunnerJobMetrics already succeeded for
+// the current Completed phase. Both are reset to false only when the tuple
+// they guard changes, and set to true only by
+// markEventDelivered/markMetricsPushed after a confirmed success — never
+// unconditionally when the tuple is merely observed. This keeps a failed
+// delivery/push retriable: Reconcile returning an error requeues through the
+// controller's rate limiter, and since the flag wasn't advanced, the next
+// reconcile (which observes the same tuple) tries again instead of treating
+// it as already delivered/pushed.
+type observedRunnerState struct {
+	phase                     string
+	registrationToken         string
+	registrationExpiresAt     metav1.Time
+	lastRegistrationCheckTime metav1.Time
+
+	phaseChangedAt time.Time
+	runningSince   time.Time
+	busySince      time.Time
+
+	eventDelivered bool
+	metricsPushed  bool
+}
+
+func (r *RunnerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Runner{}).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=actions.summerwind.dev,resources=runners,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=actions.summerwind.dev,resources=runners/status,verbs=get;update;patch
+
+func (r *RunnerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var runner v1alpha1.Runner
+	if err := r.Get(ctx, req.NamespacedName, &runner); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forgetObserved(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	metricsPushEnabled := r.resolveMetricsPushSpec(runner.Namespace, runner.Spec) != nil
+
+	if !runner.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(&runner, metricsPushFinalizerName) {
+			if err := r.deleteRunnerJobMetrics(ctx, &runner); err != nil {
+				return ctrl.Result{}, err
+			}
+			controllerutil.RemoveFinalizer(&runner, metricsPushFinalizerName)
+			if err := r.Update(ctx, &runner); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		r.forgetObserved(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	if metricsPushEnabled && !controllerutil.ContainsFinalizer(&runner, metricsPushFinalizerName) {
+		controllerutil.AddFinalizer(&runner, metricsPushFinalizerName)
+		if err := r.Update(ctx, &runner); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	now := time.Now()
+	next, prev, _, _ := r.recordObserved(req.NamespacedName, runner, now)
+
+	if !next.eventDelivered {
+		if err := r.deliverRunnerEvent(ctx, &runner); err != nil {
+			r.Log.Error(err, "delivering runner lifecycle event", "runner", req.NamespacedName)
+			return ctrl.Result{}, err
+		}
+		r.markEventDelivered(req.NamespacedName)
+	}
+
+	if metricsPushEnabled && !next.metricsPushed && runner.Status.Phase == "Completed" && !prev.busySince.IsZero() {
+		metrics := jobMetrics{
+			JobDurationSeconds: now.Sub(prev.busySince).Seconds(),
+			ExitCode:           annotationInt64(runner.Annotations, exitCodeAnnotation),
+			DockerPullBytes:    annotationInt64(runner.Annotations, dockerPullBytesAnnotation),
+		}
+		if !prev.runningSince.IsZero() {
+			metrics.QueueWaitSeconds = prev.busySince.Sub(prev.runningSince).Seconds()
+		}
+
+		if err := r.pushRunnerJobMetrics(ctx, &runner, metrics); err != nil {
+			r.Log.Error(err, "pushing runner job metrics", "runner", req.NamespacedName)
+			return ctrl.Result{}, err
+		}
+		r.markMetricsPushed(req.NamespacedName)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// recordObserved compares runner's current lifecycle state against the last
+// state recorded for key, records the new state, and reports it alongside
+// the previous state, whether one existed, and whether Phase changed.
+//
+// phaseChangedAt carries forward from prev when Phase didn't change.
+// runningSince/busySince carry forward from prev until Phase next changes to
+// Running/Busy respectively, so once Phase reaches Completed they still hold
+// the start of the job's Running and Busy phases. eventDelivered/metricsPushed
+// carry forward from prev only
+// when the tuple each guards (see observedRunnerState) didn't change; a
+// caller that successfully delivers/pushes for the *returned* next state
+// must call markEventDelivered/markMetricsPushed afterwards — recordObserved
+// itself never marks either true, so a failed attempt is retried on the next
+// reconcile of the same tuple instead of being silently dropped.
+func (r *RunnerReconciler) recordObserved(key types.NamespacedName, runner v1alpha1.Runner, now time.Time) (next, prev observedRunnerState, hadPrev, phaseChanged bool) {
+	r.observedMu.Lock()
+	defer r.observedMu.Unlock()
+
+	if r.observed == nil {
+		r.observed = map[types.NamespacedName]observedRunnerState{}
+	}
+
+	prev, hadPrev = r.observed[key]
+
+	next = observedRunnerState{
+		phase:                     runner.Status.Phase,
+		registrationToken:         runner.Status.Registration.Token,
+		registrationExpiresAt:     runner.Status.Registration.ExpiresAt,
+		lastRegistrationCheckTime: lastRegistrationCheckTime(runner),
+	}
+
+	phaseChanged = !hadPrev || prev.phase != next.phase
+	next.runningSince = prev.runningSince
+	next.busySince = prev.busySince
+	if phaseChanged {
+		next.phaseChangedAt = now
+		switch next.phase {
+		case "Running":
+			next.runningSince = now
+		case "Busy":
+			next.busySince = now
+		}
+	} else {
+		next.phaseChangedAt = prev.phaseChangedAt
+	}
+
+	lifecycleChanged := !hadPrev || phaseChanged ||
+		prev.registrationToken != next.registrationToken ||
+		prev.registrationExpiresAt != next.registrationExpiresAt ||
+		prev.lastRegistrationCheckTime != next.lastRegistrationCheckTime
+	if !lifecycleChanged {
+		next.eventDelivered = prev.eventDelivered
+	}
+	if !phaseChanged {
+		next.metricsPushed = prev.metricsPushed
+	}
+
+	r.observed[key] = next
+
+	return next, prev, hadPrev, phaseChanged
+}
+
+// markEventDelivered records that deliverRunnerEvent succeeded for key's
+// current observed tuple, so Reconcile won't redeliver until it changes
+// again.
+func (r *RunnerReconciler) markEventDelivered(key types.NamespacedName) {
+	r.observedMu.Lock()
+	defer r.observedMu.Unlock()
+	if s, ok := r.observed[key]; ok {
+		s.eventDelivered = true
+		r.observed[key] = s
+	}
+}
+
+// markMetricsPushed records that pushRunnerJobMetrics succeeded for key's
+// current Completed phase, so Reconcile won't re-push until Phase changes
+// again.
+func (r *RunnerReconciler) markMetricsPushed(key types.NamespacedName) {
+	r.observedMu.Lock()
+	defer r.observedMu.Unlock()
+	if s, ok := r.observed[key]; ok {
+		s.metricsPushed = true
+		r.observed[key] = s
+	}
+}
+
+func (r *RunnerReconciler) forgetObserved(key types.NamespacedName) {
+	r.observedMu.Lock()
+	defer r.observedMu.Unlock()
+	delete(r.observed, key)
+}
+
+// lastRegistrationCheckTime returns runner's LastRegistrationCheckTime, or
+// the zero value when unset.
+func lastRegistrationCheckTime(runner v1alpha1.Runner) metav1.Time {
+	if runner.Status.LastRegistrationCheckTime == nil {
+		return metav1.Time{}
+	}
+	return *runner.Status.LastRegistrationCheckTime
+}
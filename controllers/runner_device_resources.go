@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+const (
+	multusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+
+	qatResourceName = "qat.intel.com/generic"
+)
+
+// multusNetworkSelection is a single entry of the Multus
+// k8s.v1.cni.cncf.io/networks annotation.
+type multusNetworkSelection struct {
+	Name string `json:"name"`
+}
+
+// applyDeviceResources translates spec's GPUs, SRIOV, and QAT requests into
+// the runner container's resource limits, the Multus networks annotation,
+// and node selectors/tolerations matching common device-plugin taints. It is
+// called while materializing the runner Pod, alongside the rest of the
+// Containers/NodeSelector/Tolerations overrides.
+func applyDeviceResources(pod *corev1.Pod, container *corev1.Container, spec v1alpha1.RunnerSpec) error {
+	if err := spec.ValidateDeviceResources(); err != nil {
+		return err
+	}
+
+	if container.Resources.Limits == nil {
+		container.Resources.Limits = corev1.ResourceList{}
+	}
+
+	if gpu := spec.GPUs; gpu != nil {
+		resourceName, err := gpuResourceName(gpu)
+		if err != nil {
+			return err
+		}
+		container.Resources.Limits[corev1.ResourceName(resourceName)] = *resource.NewQuantity(gpu.Count, resource.DecimalSI)
+
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = map[string]string{}
+		}
+		pod.Spec.NodeSelector[fmt.Sprintf("%s.com/gpu.present", gpu.Vendor)] = "true"
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, corev1.Toleration{
+			Key:      fmt.Sprintf("%s.com/gpu", gpu.Vendor),
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+
+	if len(spec.SRIOV) > 0 {
+		networks := make([]multusNetworkSelection, 0, len(spec.SRIOV))
+		for _, iface := range spec.SRIOV {
+			resourceName := corev1.ResourceName("intel.com/sriov_netdevice_" + iface.NetworkName)
+			count := iface.Count
+			if count == 0 {
+				count = 1
+			}
+			container.Resources.Limits[resourceName] = *resource.NewQuantity(count, resource.DecimalSI)
+			networks = append(networks, multusNetworkSelection{Name: iface.NetworkName})
+		}
+
+		annotation, err := json.Marshal(networks)
+		if err != nil {
+			return fmt.Errorf("marshaling multus networks annotation: %w", err)
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[multusNetworksAnnotation] = string(annotation)
+
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, corev1.Toleration{
+			Key:      "sriov",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+
+	if qat := spec.QAT; qat != nil {
+		container.Resources.Limits[corev1.ResourceName(qatResourceName)] = *resource.NewQuantity(qat.Count, resource.DecimalSI)
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, corev1.Toleration{
+			Key:      "qat",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+
+	return nil
+}
+
+// gpuResourceName returns the resources.limits key for gpu's vendor, e.g.
+// "nvidia.com/gpu" or "nvidia.com/mig-1g.5gb".
+func gpuResourceName(gpu *v1alpha1.GPURequest) (string, error) {
+	if gpu.MIGProfile != "" {
+		if gpu.Vendor != "nvidia" {
+			return "", fmt.Errorf("MIG profiles are only supported for vendor %q, got %q", "nvidia", gpu.Vendor)
+		}
+		return "nvidia.com/mig-" + gpu.MIGProfile, nil
+	}
+
+	switch gpu.Vendor {
+	case "nvidia", "amd":
+		return gpu.Vendor + ".com/gpu", nil
+	case "intel":
+		return "gpu.intel.com/i915", nil
+	default:
+		return "", fmt.Errorf("unsupported GPU vendor %q", gpu.Vendor)
+	}
+}
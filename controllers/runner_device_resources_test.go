@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+func TestGPUResourceName(t *testing.T) {
+	cases := []struct {
+		name    string
+		gpu     v1alpha1.GPURequest
+		want    string
+		wantErr bool
+	}{
+		{"nvidia whole gpu", v1alpha1.GPURequest{Vendor: "nvidia", Count: 1}, "nvidia.com/gpu", false},
+		{"amd whole gpu", v1alpha1.GPURequest{Vendor: "amd", Count: 1}, "amd.com/gpu", false},
+		{"intel whole gpu", v1alpha1.GPURequest{Vendor: "intel", Count: 1}, "gpu.intel.com/i915", false},
+		{"nvidia mig profile", v1alpha1.GPURequest{Vendor: "nvidia", Count: 1, MIGProfile: "1g.5gb"}, "nvidia.com/mig-1g.5gb", false},
+		{"mig profile on non-nvidia vendor", v1alpha1.GPURequest{Vendor: "amd", Count: 1, MIGProfile: "1g.5gb"}, "", true},
+		{"unsupported vendor", v1alpha1.GPURequest{Vendor: "bogus", Count: 1}, "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := gpuResourceName(&tc.gpu)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("gpuResourceName() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Fatalf("gpuResourceName() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyDeviceResourcesGPU(t *testing.T) {
+	pod := &corev1.Pod{}
+	container := &corev1.Container{}
+	spec := v1alpha1.RunnerSpec{GPUs: &v1alpha1.GPURequest{Vendor: "nvidia", Count: 2}}
+
+	if err := applyDeviceResources(pod, container, spec); err != nil {
+		t.Fatalf("applyDeviceResources() error = %v", err)
+	}
+
+	limit, ok := container.Resources.Limits["nvidia.com/gpu"]
+	if !ok || limit.Value() != 2 {
+		t.Fatalf("container.Resources.Limits[nvidia.com/gpu] = %v, ok %v, want 2", limit, ok)
+	}
+	if pod.Spec.NodeSelector["nvidia.com/gpu.present"] != "true" {
+		t.Fatalf("pod.Spec.NodeSelector = %v, want nvidia.com/gpu.present=true", pod.Spec.NodeSelector)
+	}
+	if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Key != "nvidia.com/gpu" {
+		t.Fatalf("pod.Spec.Tolerations = %v, want one toleration for nvidia.com/gpu", pod.Spec.Tolerations)
+	}
+}
+
+func TestApplyDeviceResourcesSRIOV(t *testing.T) {
+	pod := &corev1.Pod{}
+	container := &corev1.Container{}
+	spec := v1alpha1.RunnerSpec{SRIOV: []v1alpha1.SRIOVInterface{
+		{NetworkName: "dataplane", Count: 2},
+		{NetworkName: "mgmt"},
+	}}
+
+	if err := applyDeviceResources(pod, container, spec); err != nil {
+		t.Fatalf("applyDeviceResources() error = %v", err)
+	}
+
+	if limit := container.Resources.Limits["intel.com/sriov_netdevice_dataplane"]; limit.Value() != 2 {
+		t.Fatalf("intel.com/sriov_netdevice_dataplane = %v, want 2", limit)
+	}
+	if limit := container.Resources.Limits["intel.com/sriov_netdevice_mgmt"]; limit.Value() != 1 {
+		t.Fatalf("intel.com/sriov_netdevice_mgmt = %v, want 1 (Count defaults to 1)", limit)
+	}
+
+	annotation, ok := pod.Annotations[multusNetworksAnnotation]
+	if !ok {
+		t.Fatalf("pod.Annotations[%q] not set", multusNetworksAnnotation)
+	}
+	want := `[{"name":"dataplane"},{"name":"mgmt"}]`
+	if annotation != want {
+		t.Fatalf("pod.Annotations[%q] = %q, want %q", multusNetworksAnnotation, annotation, want)
+	}
+}
+
+func TestApplyDeviceResourcesQAT(t *testing.T) {
+	pod := &corev1.Pod{}
+	container := &corev1.Container{}
+	spec := v1alpha1.RunnerSpec{QAT: &v1alpha1.QATRequest{Count: 1}}
+
+	if err := applyDeviceResources(pod, container, spec); err != nil {
+		t.Fatalf("applyDeviceResources() error = %v", err)
+	}
+
+	if limit := container.Resources.Limits[qatResourceName]; limit.Value() != 1 {
+		t.Fatalf("container.Resources.Limits[%q] = %v, want 1", qatResourceName, limit)
+	}
+
+	found := false
+	for _, toleration := range pod.Spec.Tolerations {
+		if toleration.Key == "qat" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("pod.Spec.Tolerations = %v, want a qat toleration", pod.Spec.Tolerations)
+	}
+}
+
+func TestApplyDeviceResourcesInvalidSRIOV(t *testing.T) {
+	pod := &corev1.Pod{}
+	container := &corev1.Container{}
+	spec := v1alpha1.RunnerSpec{SRIOV: []v1alpha1.SRIOVInterface{{}}}
+
+	if err := applyDeviceResources(pod, container, spec); err == nil {
+		t.Fatal("applyDeviceResources() error = nil, want error for an SRIOV interface with no networkName")
+	}
+}
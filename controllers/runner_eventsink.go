@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+const (
+	eventTypeRegistered = "dev.arc.runner.registered"
+	eventTypeBusy       = "dev.arc.runner.busy"
+	eventTypeCompleted  = "dev.arc.runner.completed"
+)
+
+// runnerEventData is the CloudEvent `data` payload describing a Runner
+// lifecycle transition.
+//
+// There is no job-id source on Runner/RunnerStatus in this tree, so the
+// payload doesn't carry one; add a field here once one exists.
+type runnerEventData struct {
+	Labels    []string   `json:"labels,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Phase     string     `json:"phase"`
+}
+
+// eventSubject returns the GitHub scope the event is about, preferring
+// Repository, then Organization, then Enterprise. Only these shorthand fields
+// are read, so a Runner that sets only Spec.Targets gets an empty subject.
+func eventSubject(rs v1alpha1.RunnerSpec) string {
+	switch {
+	case rs.Repository != "":
+		return rs.Repository
+	case rs.Organization != "":
+		return rs.Organization
+	case rs.Enterprise != "":
+		return rs.Enterprise
+	default:
+		return ""
+	}
+}
+
+// eventTypeForPhase maps a Runner phase to a CloudEvent type. Unrecognized
+// phases still produce a generic event so sinks can observe them.
+func eventTypeForPhase(phase string) string {
+	switch phase {
+	case "Running":
+		return eventTypeRegistered
+	case "Busy":
+		return eventTypeBusy
+	case "Completed":
+		return eventTypeCompleted
+	default:
+		return "dev.arc.runner." + phase
+	}
+}
+
+// buildRunnerEvent constructs the CloudEvent describing the current state of
+// runner. The event ID is derived from the object's ResourceVersion, which
+// the API server bumps on every update to Status (including Registration- or
+// LastRegistrationCheckTime-only changes that leave Phase unchanged), so two
+// distinct transitions never collide on the same (source, id) pair.
+func buildRunnerEvent(runner v1alpha1.Runner) event.Event {
+	e := cloudevents.NewEvent()
+	e.SetID(fmt.Sprintf("%s-%s", runner.UID, runner.ResourceVersion))
+	e.SetSource(fmt.Sprintf("%s/%s", runner.Namespace, runner.Name))
+	e.SetSubject(eventSubject(runner.Spec))
+	e.SetType(eventTypeForPhase(runner.Status.Phase))
+	e.SetTime(time.Now())
+
+	data := runnerEventData{
+		Labels: runner.Spec.Labels,
+		Phase:  runner.Status.Phase,
+	}
+	if !runner.Status.Registration.ExpiresAt.IsZero() {
+		t := runner.Status.Registration.ExpiresAt.Time
+		data.ExpiresAt = &t
+	}
+
+	_ = e.SetData(cloudevents.ApplicationJSON, data)
+
+	return e
+}
+
+// deliverRunnerEvent sends a single CloudEvent describing runner to its
+// configured EventSink in structured mode (this controller doesn't negotiate
+// binary mode with the sink) and records the outcome as the
+// RunnerConditionEventDeliverySuccess condition. It is a no-op when
+// Spec.EventSink is nil. Reconcile calls this when no delivery has yet
+// succeeded for the current lifecycle fields (on the first reconcile of a
+// Runner, after a change, or after a failed attempt); on failure it returns
+// the error rather than sleeping
+// in-process, so the caller can requeue through the controller's rate
+// limiter (which already backs off exponentially) instead of blocking the
+// shared work queue.
+func (r *RunnerReconciler) deliverRunnerEvent(ctx context.Context, runner *v1alpha1.Runner) error {
+	sink := runner.Spec.EventSink
+	if sink == nil {
+		return nil
+	}
+
+	opts := []cehttp.Option{
+		cloudevents.WithTarget(sink.URL),
+	}
+	for k, v := range sink.Headers {
+		opts = append(opts, cloudevents.WithHeader(k, v))
+	}
+
+	if sink.SecretRef != nil {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: runner.Namespace, Name: sink.SecretRef.Name}, &secret); err != nil {
+			return fmt.Errorf("resolving event sink secret: %w", err)
+		}
+		if token, ok := secret.Data["token"]; ok {
+			opts = append(opts, cloudevents.WithHeader("Authorization", "token "+string(token)))
+		}
+	}
+
+	c, err := cloudevents.NewClientHTTP(opts...)
+	if err != nil {
+		return fmt.Errorf("creating cloudevents client: %w", err)
+	}
+
+	ev := buildRunnerEvent(*runner)
+
+	result := c.Send(cloudevents.WithEncodingStructured(ctx), ev)
+	var sendErr error
+	if !cloudevents.IsACK(result) {
+		sendErr = result
+	}
+
+	if err := r.recordEventDeliveryCondition(ctx, runner, sendErr); err != nil {
+		return err
+	}
+
+	return sendErr
+}
+
+// recordEventDeliveryCondition updates RunnerConditionEventDeliverySuccess on
+// runner.Status to reflect the outcome of the most recent delivery attempt.
+func (r *RunnerReconciler) recordEventDeliveryCondition(ctx context.Context, runner *v1alpha1.Runner, deliveryErr error) error {
+	now := metav1.Now()
+	cond := v1alpha1.RunnerCondition{
+		Type:               v1alpha1.RunnerConditionEventDeliverySuccess,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: &now,
+	}
+	if deliveryErr != nil {
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = "DeliveryFailed"
+		cond.Message = deliveryErr.Error()
+	}
+
+	updated := false
+	for i := range runner.Status.Conditions {
+		if runner.Status.Conditions[i].Type == cond.Type {
+			runner.Status.Conditions[i] = cond
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		runner.Status.Conditions = append(runner.Status.Conditions, cond)
+	}
+
+	return r.Status().Update(ctx, runner)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+func TestEventSubject(t *testing.T) {
+	cases := []struct {
+		name string
+		spec v1alpha1.RunnerSpec
+		want string
+	}{
+		{"repository", v1alpha1.RunnerSpec{Repository: "acme/widgets"}, "acme/widgets"},
+		{"organization", v1alpha1.RunnerSpec{Organization: "acme"}, "acme"},
+		{"enterprise", v1alpha1.RunnerSpec{Enterprise: "acme-corp"}, "acme-corp"},
+		{"none set", v1alpha1.RunnerSpec{}, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := eventSubject(tc.spec); got != tc.want {
+				t.Fatalf("eventSubject() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEventTypeForPhase(t *testing.T) {
+	cases := []struct {
+		phase string
+		want  string
+	}{
+		{"Running", eventTypeRegistered},
+		{"Busy", eventTypeBusy},
+		{"Completed", eventTypeCompleted},
+		{"Pending", "dev.arc.runner.Pending"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.phase, func(t *testing.T) {
+			if got := eventTypeForPhase(tc.phase); got != tc.want {
+				t.Fatalf("eventTypeForPhase(%q) = %q, want %q", tc.phase, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildRunnerEvent(t *testing.T) {
+	expiresAt := metav1.NewTime(time.Date(2026, 7, 27, 12, 0, 0, 0, time.UTC))
+	runner := v1alpha1.Runner{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "runner-1",
+			Namespace:       "default",
+			UID:             types.UID("abc-123"),
+			ResourceVersion: "42",
+		},
+		Spec: v1alpha1.RunnerSpec{
+			Repository: "acme/widgets",
+			Labels:     []string{"linux", "x64"},
+		},
+		Status: v1alpha1.RunnerStatus{
+			Phase:        "Busy",
+			Registration: v1alpha1.RunnerStatusRegistration{ExpiresAt: expiresAt},
+		},
+	}
+
+	ev := buildRunnerEvent(runner)
+
+	if want := "abc-123-42"; ev.ID() != want {
+		t.Fatalf("ev.ID() = %q, want %q", ev.ID(), want)
+	}
+	if want := "default/runner-1"; ev.Source() != want {
+		t.Fatalf("ev.Source() = %q, want %q", ev.Source(), want)
+	}
+	if ev.Subject() != "acme/widgets" {
+		t.Fatalf("ev.Subject() = %q, want %q", ev.Subject(), "acme/widgets")
+	}
+	if ev.Type() != eventTypeBusy {
+		t.Fatalf("ev.Type() = %q, want %q", ev.Type(), eventTypeBusy)
+	}
+
+	var data runnerEventData
+	if err := ev.DataAs(&data); err != nil {
+		t.Fatalf("ev.DataAs() error = %v", err)
+	}
+	if len(data.Labels) != 2 || data.Labels[0] != "linux" || data.Labels[1] != "x64" {
+		t.Fatalf("data.Labels = %v, want [linux x64]", data.Labels)
+	}
+	if data.Phase != "Busy" {
+		t.Fatalf("data.Phase = %q, want %q", data.Phase, "Busy")
+	}
+	if data.ExpiresAt == nil || !data.ExpiresAt.Equal(expiresAt.Time) {
+		t.Fatalf("data.ExpiresAt = %v, want %v", data.ExpiresAt, expiresAt.Time)
+	}
+}
+
+func TestBuildRunnerEventNoExpiry(t *testing.T) {
+	runner := v1alpha1.Runner{
+		ObjectMeta: metav1.ObjectMeta{Name: "runner-1", Namespace: "default", ResourceVersion: "1"},
+		Status:     v1alpha1.RunnerStatus{Phase: "Running"},
+	}
+
+	ev := buildRunnerEvent(runner)
+
+	var data runnerEventData
+	if err := ev.DataAs(&data); err != nil {
+		t.Fatalf("ev.DataAs() error = %v", err)
+	}
+	if data.ExpiresAt != nil {
+		t.Fatalf("data.ExpiresAt = %v, want nil when Registration.ExpiresAt is unset", data.ExpiresAt)
+	}
+}
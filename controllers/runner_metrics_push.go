@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+const defaultMetricsPushJobLabel = "github-runner"
+
+const (
+	// exitCodeAnnotation and dockerPullBytesAnnotation are set on the Runner
+	// object by the runner agent or an injected SidecarContainers sidecar
+	// before the controller observes the Completed phase, since neither
+	// value is otherwise visible to the controller.
+	exitCodeAnnotation        = "actions-runner-controller/exit-code"
+	dockerPullBytesAnnotation = "actions-runner-controller/docker-pull-bytes"
+)
+
+// annotationInt64 parses annotations[key] as a base-10 integer, returning 0
+// when the key is absent or not a valid integer.
+func annotationInt64(annotations map[string]string, key string) int64 {
+	v, ok := annotations[key]
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// jobMetrics is the set of per-job gauges pushed to the Pushgateway when a
+// Runner's job completes.
+type jobMetrics struct {
+	JobDurationSeconds float64
+	ExitCode           int64
+	QueueWaitSeconds   float64
+	DockerPullBytes    int64
+}
+
+// resolveMetricsPushSpec returns spec's MetricsPush, falling back to
+// r.DefaultMetricsPushURL[namespace] when the Runner didn't set one. The
+// latter is keyed by namespace and populated from the controller manager's
+// repeatable `--default-metrics-push-url namespace=url` flag. It returns nil
+// when neither is configured.
+func (r *RunnerReconciler) resolveMetricsPushSpec(namespace string, spec v1alpha1.RunnerSpec) *v1alpha1.MetricsPushSpec {
+	if spec.MetricsPush != nil {
+		return spec.MetricsPush
+	}
+	if r.DefaultMetricsPushURL == nil {
+		return nil
+	}
+	url, ok := r.DefaultMetricsPushURL[namespace]
+	if !ok || url == "" {
+		return nil
+	}
+	return &v1alpha1.MetricsPushSpec{URL: url}
+}
+
+// pushRunnerJobMetrics pushes metrics for runner's most recently completed
+// job to the configured Pushgateway, grouped by job label and the runner's
+// name as instance.
+func (r *RunnerReconciler) pushRunnerJobMetrics(ctx context.Context, runner *v1alpha1.Runner, metrics jobMetrics) error {
+	spec := r.resolveMetricsPushSpec(runner.Namespace, runner.Spec)
+	if spec == nil {
+		return nil
+	}
+
+	pusher, err := r.newPusher(ctx, runner, spec)
+	if err != nil {
+		return err
+	}
+
+	jobDuration := prometheus.NewGauge(prometheus.GaugeOpts{Name: "job_duration_seconds"})
+	jobDuration.Set(metrics.JobDurationSeconds)
+	exitCode := prometheus.NewGauge(prometheus.GaugeOpts{Name: "job_exit_code"})
+	exitCode.Set(float64(metrics.ExitCode))
+	queueWait := prometheus.NewGauge(prometheus.GaugeOpts{Name: "job_queue_wait_seconds"})
+	queueWait.Set(metrics.QueueWaitSeconds)
+	pullBytes := prometheus.NewGauge(prometheus.GaugeOpts{Name: "docker_pull_bytes"})
+	pullBytes.Set(float64(metrics.DockerPullBytes))
+
+	pusher = pusher.
+		Collector(jobDuration).
+		Collector(exitCode).
+		Collector(queueWait).
+		Collector(pullBytes)
+
+	if err := pusher.Push(); err != nil {
+		return fmt.Errorf("pushing job metrics to pushgateway: %w", err)
+	}
+
+	return nil
+}
+
+// deleteRunnerJobMetrics removes runner's metric series from the
+// Pushgateway. It is called when the Runner object is finalized so stale
+// series for a deleted ephemeral runner don't accumulate.
+func (r *RunnerReconciler) deleteRunnerJobMetrics(ctx context.Context, runner *v1alpha1.Runner) error {
+	spec := r.resolveMetricsPushSpec(runner.Namespace, runner.Spec)
+	if spec == nil {
+		return nil
+	}
+
+	pusher, err := r.newPusher(ctx, runner, spec)
+	if err != nil {
+		return err
+	}
+
+	if err := pusher.Delete(); err != nil {
+		return fmt.Errorf("deleting job metrics from pushgateway: %w", err)
+	}
+
+	return nil
+}
+
+// newPusher builds the push.Pusher used to PUT/DELETE runner's metrics under
+// the job/instance/GroupingLabels grouping key.
+func (r *RunnerReconciler) newPusher(ctx context.Context, runner *v1alpha1.Runner, spec *v1alpha1.MetricsPushSpec) (*push.Pusher, error) {
+	jobLabel := spec.JobLabel
+	if jobLabel == "" {
+		jobLabel = defaultMetricsPushJobLabel
+	}
+
+	pusher := push.New(spec.URL, jobLabel).Grouping("instance", runner.Name)
+	for k, v := range spec.GroupingLabels {
+		pusher = pusher.Grouping(k, v)
+	}
+
+	if spec.BasicAuthSecretRef != nil {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: runner.Namespace, Name: spec.BasicAuthSecretRef.Name}, &secret); err != nil {
+			return nil, fmt.Errorf("resolving metrics push basic auth secret: %w", err)
+		}
+		pusher = pusher.BasicAuth(string(secret.Data["username"]), string(secret.Data["password"]))
+	}
+
+	return pusher, nil
+}
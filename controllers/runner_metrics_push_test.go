@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+func TestResolveMetricsPushSpecExplicit(t *testing.T) {
+	r := &RunnerReconciler{}
+	spec := v1alpha1.RunnerSpec{MetricsPush: &v1alpha1.MetricsPushSpec{URL: "http://explicit:9091"}}
+
+	got := r.resolveMetricsPushSpec("default", spec)
+	if got == nil || got.URL != "http://explicit:9091" {
+		t.Fatalf("resolveMetricsPushSpec() = %v, want the Spec.MetricsPush set on the Runner", got)
+	}
+}
+
+func TestResolveMetricsPushSpecDefaultURLFallback(t *testing.T) {
+	r := &RunnerReconciler{DefaultMetricsPushURL: map[string]string{"ci": "http://default:9091"}}
+
+	got := r.resolveMetricsPushSpec("ci", v1alpha1.RunnerSpec{})
+	if got == nil || got.URL != "http://default:9091" {
+		t.Fatalf("resolveMetricsPushSpec() = %v, want the namespace's DefaultMetricsPushURL", got)
+	}
+
+	if got := r.resolveMetricsPushSpec("other-namespace", v1alpha1.RunnerSpec{}); got != nil {
+		t.Fatalf("resolveMetricsPushSpec() = %v, want nil for a namespace with no default configured", got)
+	}
+}
+
+func TestResolveMetricsPushSpecNoneConfigured(t *testing.T) {
+	r := &RunnerReconciler{}
+
+	if got := r.resolveMetricsPushSpec("default", v1alpha1.RunnerSpec{}); got != nil {
+		t.Fatalf("resolveMetricsPushSpec() = %v, want nil when neither Spec.MetricsPush nor DefaultMetricsPushURL is set", got)
+	}
+}
+
+func TestAnnotationInt64(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		key         string
+		want        int64
+	}{
+		{"present and valid", map[string]string{"k": "42"}, "k", 42},
+		{"missing key", map[string]string{}, "k", 0},
+		{"nil map", nil, "k", 0},
+		{"not an integer", map[string]string{"k": "oops"}, "k", 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := annotationInt64(tc.annotations, tc.key); got != tc.want {
+				t.Fatalf("annotationInt64() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewPusherJobLabelDefault(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotPath = req.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	r := &RunnerReconciler{}
+	runner := &v1alpha1.Runner{}
+	runner.Name = "runner-1"
+	spec := &v1alpha1.MetricsPushSpec{URL: server.URL}
+
+	pusher, err := r.newPusher(context.Background(), runner, spec)
+	if err != nil {
+		t.Fatalf("newPusher() error = %v", err)
+	}
+	if pusher == nil {
+		t.Fatal("newPusher() = nil pusher, want a non-nil *push.Pusher")
+	}
+
+	if err := pusher.Delete(); err != nil {
+		t.Fatalf("pusher.Delete() error = %v", err)
+	}
+	if want := "/metrics/job/" + defaultMetricsPushJobLabel + "/instance/runner-1"; gotPath != want {
+		t.Fatalf("pushgateway request path = %q, want %q", gotPath, want)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+const (
+	runnerContainerName = "runner"
+
+	// runnerURLsEnvName lists the registration URL of every target whose
+	// Schedule is active, primary target first, for the runner agent's
+	// `--url` rotation.
+	runnerURLsEnvName = "RUNNER_URLS"
+)
+
+// newRunnerPod materializes the Pod for runner: the base runner container
+// plus the spec-driven overrides (Containers, init/sidecar containers,
+// volumes, node placement, and device-plugin resources).
+func newRunnerPod(runner *v1alpha1.Runner) (*corev1.Pod, error) {
+	spec := runner.Spec
+
+	container := corev1.Container{
+		Name:            runnerContainerName,
+		Image:           spec.Image,
+		ImagePullPolicy: spec.ImagePullPolicy,
+		Env:             spec.Env,
+		EnvFrom:         spec.EnvFrom,
+		Resources:       spec.Resources,
+		VolumeMounts:    spec.VolumeMounts,
+		WorkingDir:      spec.WorkDir,
+	}
+
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == runnerContainerName {
+			container = spec.Containers[i]
+			break
+		}
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      runner.Name,
+			Namespace: runner.Namespace,
+			Labels:    map[string]string{"runner-name": runner.Name},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			Containers:                    append([]corev1.Container{container}, spec.SidecarContainers...),
+			InitContainers:                spec.InitContainers,
+			EphemeralContainers:           spec.EphemeralContainers,
+			Volumes:                       spec.Volumes,
+			NodeSelector:                  spec.NodeSelector,
+			ServiceAccountName:            spec.ServiceAccountName,
+			AutomountServiceAccountToken:  spec.AutomountServiceAccountToken,
+			SecurityContext:               spec.SecurityContext,
+			ImagePullSecrets:              spec.ImagePullSecrets,
+			Affinity:                      spec.Affinity,
+			Tolerations:                   spec.Tolerations,
+			TerminationGracePeriodSeconds: spec.TerminationGracePeriodSeconds,
+		},
+	}
+
+	if err := applyDeviceResources(pod, &pod.Spec.Containers[0], spec); err != nil {
+		return nil, fmt.Errorf("applying device resources: %w", err)
+	}
+
+	urls, err := runnerURLRotation(spec, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("resolving registration targets: %w", err)
+	}
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  runnerURLsEnvName,
+		Value: strings.Join(urls, ","),
+	})
+
+	return pod, nil
+}
+
+// runnerURLRotation returns the registration URLs for every target in
+// spec.EffectiveTargets whose Schedule is active at now (see
+// scheduledTargets), with the target selectRegistrationTarget currently
+// favors (by Weight) moved to the front so the runner agent's `--url`
+// rotation tries it first.
+func runnerURLRotation(spec v1alpha1.RunnerSpec, now time.Time) ([]string, error) {
+	targets := spec.EffectiveTargets()
+
+	active, err := scheduledTargets(targets, now)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, err := selectRegistrationTarget(targets, now)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]v1alpha1.RunnerTarget, 0, len(active))
+	ordered = append(ordered, *primary)
+	for _, target := range active {
+		if sameTarget(target, *primary) {
+			continue
+		}
+		ordered = append(ordered, target)
+	}
+
+	return registrationURLs(ordered), nil
+}
+
+// sameTarget compares the scope-identifying fields of two RunnerTargets.
+// RunnerTarget isn't comparable with == because Labels is a slice.
+func sameTarget(a, b v1alpha1.RunnerTarget) bool {
+	return a.Enterprise == b.Enterprise &&
+		a.Organization == b.Organization &&
+		a.Repository == b.Repository &&
+		a.Group == b.Group &&
+		a.Weight == b.Weight &&
+		a.Schedule == b.Schedule
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+func TestNewRunnerPod(t *testing.T) {
+	runner := &v1alpha1.Runner{
+		ObjectMeta: metav1.ObjectMeta{Name: "runner-1", Namespace: "default"},
+		Spec: v1alpha1.RunnerSpec{
+			Repository: "acme/widgets",
+			Image:      "summerwind/actions-runner",
+			GPUs:       &v1alpha1.GPURequest{Vendor: "nvidia", Count: 1},
+		},
+	}
+
+	pod, err := newRunnerPod(runner)
+	if err != nil {
+		t.Fatalf("newRunnerPod() error = %v", err)
+	}
+
+	if pod.Name != runner.Name || pod.Namespace != runner.Namespace {
+		t.Fatalf("pod = %s/%s, want %s/%s", pod.Namespace, pod.Name, runner.Namespace, runner.Name)
+	}
+	if len(pod.Spec.Containers) != 1 || pod.Spec.Containers[0].Name != runnerContainerName {
+		t.Fatalf("pod.Spec.Containers = %v, want a single %q container", pod.Spec.Containers, runnerContainerName)
+	}
+
+	container := pod.Spec.Containers[0]
+	if _, ok := container.Resources.Limits["nvidia.com/gpu"]; !ok {
+		t.Fatalf("container.Resources.Limits = %v, want applyDeviceResources to have set nvidia.com/gpu", container.Resources.Limits)
+	}
+
+	var urls string
+	for _, env := range container.Env {
+		if env.Name == runnerURLsEnvName {
+			urls = env.Value
+		}
+	}
+	if urls != "https://github.com/acme/widgets" {
+		t.Fatalf("%s env = %q, want the single target's registration URL", runnerURLsEnvName, urls)
+	}
+}
+
+func TestNewRunnerPodCustomRunnerContainerOverride(t *testing.T) {
+	// Overriding the "runner" container by name in Containers should be used
+	// verbatim instead of the default container built from the top-level
+	// Image/Env/Resources/... fields, while device resources and the
+	// RUNNER_URLS env var are still applied to it.
+	runner := &v1alpha1.Runner{
+		ObjectMeta: metav1.ObjectMeta{Name: "runner-1", Namespace: "default"},
+		Spec: v1alpha1.RunnerSpec{
+			Organization: "acme",
+			Image:        "summerwind/actions-runner",
+			Containers: []corev1.Container{
+				{Name: runnerContainerName, Image: "custom-image"},
+			},
+		},
+	}
+
+	pod, err := newRunnerPod(runner)
+	if err != nil {
+		t.Fatalf("newRunnerPod() error = %v", err)
+	}
+	if len(pod.Spec.Containers) != 1 {
+		t.Fatalf("pod.Spec.Containers = %v, want exactly the overridden container", pod.Spec.Containers)
+	}
+	if pod.Spec.Containers[0].Image != "custom-image" {
+		t.Fatalf("pod.Spec.Containers[0].Image = %q, want %q", pod.Spec.Containers[0].Image, "custom-image")
+	}
+
+	foundURLs := false
+	for _, env := range pod.Spec.Containers[0].Env {
+		if env.Name == runnerURLsEnvName {
+			foundURLs = true
+		}
+	}
+	if !foundURLs {
+		t.Fatalf("pod.Spec.Containers[0].Env = %v, want it to include %s", pod.Spec.Containers[0].Env, runnerURLsEnvName)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+// matchesSchedule reports whether sched fires during now's minute. Standard
+// cron expressions name specific minutes, not windows: "0 9 * * 1-5" fires
+// only at 9:00, not "9am-5pm". To keep a target active across a window,
+// write a range into the relevant field, e.g. "* 9-17 * * 1-5" for business
+// hours.
+func matchesSchedule(sched cron.Schedule, now time.Time) bool {
+	truncated := now.Truncate(time.Minute)
+	return sched.Next(truncated.Add(-time.Second)).Equal(truncated)
+}
+
+// scheduledTargets returns the targets that set no Schedule together with
+// those whose Schedule matches now's minute (see matchesSchedule), in their
+// original order.
+func scheduledTargets(targets []v1alpha1.RunnerTarget, now time.Time) ([]v1alpha1.RunnerTarget, error) {
+	active := make([]v1alpha1.RunnerTarget, 0, len(targets))
+	anyScheduled := false
+
+	for _, target := range targets {
+		if target.Schedule == "" {
+			active = append(active, target)
+			continue
+		}
+		anyScheduled = true
+
+		sched, err := cron.ParseStandard(target.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("parsing schedule %q: %w", target.Schedule, err)
+		}
+		if matchesSchedule(sched, now) {
+			active = append(active, target)
+		}
+	}
+
+	if len(active) == 0 && anyScheduled {
+		return nil, nil
+	}
+
+	return active, nil
+}
+
+// selectRegistrationTarget picks the target a newly-created Runner should
+// register against first, distributing registrations across targets.Weight
+// (defaulting unset weights to 1) among the targets whose Schedule is
+// currently active.
+func selectRegistrationTarget(targets []v1alpha1.RunnerTarget, now time.Time) (*v1alpha1.RunnerTarget, error) {
+	active, err := scheduledTargets(targets, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 {
+		return nil, fmt.Errorf("no target is currently within its schedule")
+	}
+
+	totalWeight := int64(0)
+	weights := make([]int64, len(active))
+	for i, target := range active {
+		w := int64(target.Weight)
+		if w <= 0 {
+			w = 1
+		}
+		weights[i] = w
+		totalWeight += w
+	}
+
+	pick := rand.Int63n(totalWeight)
+	for i, w := range weights {
+		if pick < w {
+			return &active[i], nil
+		}
+		pick -= w
+	}
+
+	// Unreachable: weights always sum to totalWeight.
+	return &active[len(active)-1], nil
+}
+
+// registrationURLs returns the GitHub URLs for every target, in order, for
+// use with the runner agent's `--url` rotation so a single pod can be
+// registered against more than one scope.
+func registrationURLs(targets []v1alpha1.RunnerTarget) []string {
+	urls := make([]string, 0, len(targets))
+	for _, target := range targets {
+		switch {
+		case target.Repository != "":
+			urls = append(urls, "https://github.com/"+target.Repository)
+		case target.Organization != "":
+			urls = append(urls, "https://github.com/"+target.Organization)
+		case target.Enterprise != "":
+			urls = append(urls, "https://github.com/enterprises/"+target.Enterprise)
+		}
+	}
+
+	return urls
+}
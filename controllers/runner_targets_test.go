@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/thejasn/actions-runner-controller/api/v1alpha1"
+)
+
+func TestScheduledTargetsNoSchedule(t *testing.T) {
+	targets := []v1alpha1.RunnerTarget{{Repository: "acme/widgets"}}
+
+	active, err := scheduledTargets(targets, time.Now())
+	if err != nil {
+		t.Fatalf("scheduledTargets() error = %v", err)
+	}
+	if len(active) != 1 {
+		t.Fatalf("scheduledTargets() = %v, want 1 target with no Schedule set", active)
+	}
+}
+
+func TestScheduledTargetsMatchesOnlyTheFiringMinute(t *testing.T) {
+	// "0 9 * * *" fires only during the 09:00 minute, not all day: a plain
+	// fire-time expression is not a business-hours window.
+	businessHours := v1alpha1.RunnerTarget{Organization: "acme", Schedule: "0 9 * * *"}
+	overnight := v1alpha1.RunnerTarget{Repository: "acme/widgets"}
+	targets := []v1alpha1.RunnerTarget{businessHours, overnight}
+
+	at0900 := time.Date(2026, 7, 27, 9, 0, 0, 0, time.UTC)
+	active, err := scheduledTargets(targets, at0900)
+	if err != nil {
+		t.Fatalf("scheduledTargets() error = %v", err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("scheduledTargets() at 09:00 = %v, want both targets active", active)
+	}
+
+	at1000 := time.Date(2026, 7, 27, 10, 0, 0, 0, time.UTC)
+	active, err = scheduledTargets(targets, at1000)
+	if err != nil {
+		t.Fatalf("scheduledTargets() error = %v", err)
+	}
+	if len(active) != 1 || active[0].Organization != "" {
+		t.Fatalf("scheduledTargets() at 10:00 = %v, want only the unscheduled target active", active)
+	}
+}
+
+func TestScheduledTargetsRangeStaysActiveAcrossWindow(t *testing.T) {
+	// A genuine window requires a range in the relevant field.
+	businessHours := v1alpha1.RunnerTarget{Organization: "acme", Schedule: "* 9-17 * * *"}
+	targets := []v1alpha1.RunnerTarget{businessHours}
+
+	for _, hour := range []int{9, 12, 17} {
+		at := time.Date(2026, 7, 27, hour, 30, 0, 0, time.UTC)
+		active, err := scheduledTargets(targets, at)
+		if err != nil {
+			t.Fatalf("scheduledTargets() error = %v", err)
+		}
+		if len(active) != 1 {
+			t.Fatalf("scheduledTargets() at %02d:30 = %v, want target active", hour, active)
+		}
+	}
+
+	outsideWindow := time.Date(2026, 7, 27, 20, 0, 0, 0, time.UTC)
+	active, err := scheduledTargets(targets, outsideWindow)
+	if err != nil {
+		t.Fatalf("scheduledTargets() error = %v", err)
+	}
+	if len(active) != 0 {
+		t.Fatalf("scheduledTargets() at 20:00 = %v, want no target active", active)
+	}
+}
+
+func TestSelectRegistrationTargetNoneActive(t *testing.T) {
+	targets := []v1alpha1.RunnerTarget{{Organization: "acme", Schedule: "0 9 * * *"}}
+
+	if _, err := selectRegistrationTarget(targets, time.Date(2026, 7, 27, 20, 0, 0, 0, time.UTC)); err == nil {
+		t.Fatal("selectRegistrationTarget() error = nil, want error when no target is within its schedule")
+	}
+}
+
+func TestSelectRegistrationTargetWeightedDistribution(t *testing.T) {
+	heavy := v1alpha1.RunnerTarget{Organization: "acme", Weight: 9}
+	light := v1alpha1.RunnerTarget{Repository: "acme/widgets", Weight: 1}
+	targets := []v1alpha1.RunnerTarget{heavy, light}
+
+	counts := map[string]int{}
+	for i := 0; i < 500; i++ {
+		picked, err := selectRegistrationTarget(targets, time.Now())
+		if err != nil {
+			t.Fatalf("selectRegistrationTarget() error = %v", err)
+		}
+		counts[picked.Organization+picked.Repository]++
+	}
+
+	if counts["acme"] <= counts["acme/widgets"] {
+		t.Fatalf("selectRegistrationTarget() distribution = %v, want the weight-9 target picked more often than the weight-1 target", counts)
+	}
+}
+
+func TestRegistrationURLs(t *testing.T) {
+	targets := []v1alpha1.RunnerTarget{
+		{Repository: "acme/widgets"},
+		{Organization: "acme"},
+		{Enterprise: "acme-corp"},
+	}
+
+	urls := registrationURLs(targets)
+	want := []string{
+		"https://github.com/acme/widgets",
+		"https://github.com/acme",
+		"https://github.com/enterprises/acme-corp",
+	}
+	if len(urls) != len(want) {
+		t.Fatalf("registrationURLs() = %v, want %v", urls, want)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Fatalf("registrationURLs()[%d] = %q, want %q", i, urls[i], want[i])
+		}
+	}
+}
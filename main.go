@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The actions-runner-controller authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/thejasn/actions-runner-controller/api/v1alpha1"
+	"github.com/thejasn/actions-runner-controller/controllers"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+}
+
+// namespaceURLFlag collects repeatable `--default-metrics-push-url
+// namespace=url` flags into a namespace-keyed map.
+type namespaceURLFlag map[string]string
+
+func (f namespaceURLFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for ns, url := range f {
+		pairs = append(pairs, ns+"="+url)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f namespaceURLFlag) Set(value string) error {
+	namespace, url, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected namespace=url, got %q", value)
+	}
+	f[namespace] = url
+	return nil
+}
+
+func main() {
+	var metricsAddr string
+	var enableLeaderElection bool
+	defaultMetricsPushURL := namespaceURLFlag{}
+
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
+		"Enable leader election for controller manager.")
+	flag.Var(&defaultMetricsPushURL, "default-metrics-push-url",
+		"Default Pushgateway URL for Runners that don't set spec.metricsPush, as namespace=url. May be repeated.")
+	flag.Parse()
+
+	ctrl.SetLogger(ctrl.Log)
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: metricsAddr,
+		LeaderElection:     enableLeaderElection,
+		LeaderElectionID:   "actions-runner-controller",
+	})
+	if err != nil {
+		ctrl.Log.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	reconciler := &controllers.RunnerReconciler{
+		Client:                mgr.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("Runner"),
+		Scheme:                mgr.GetScheme(),
+		DefaultMetricsPushURL: defaultMetricsPushURL,
+	}
+
+	if err := reconciler.SetupWithManager(mgr); err != nil {
+		ctrl.Log.Error(err, "unable to create controller", "controller", "Runner")
+		os.Exit(1)
+	}
+
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		ctrl.Log.Error(err, "problem running manager")
+		os.Exit(1)
+	}
+}